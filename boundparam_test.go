@@ -0,0 +1,29 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanner_Scan_BoundParam(t *testing.T) {
+	tests := []struct {
+		input string
+		tok   Token
+		lit   string
+	}{
+		{"$name", BOUNDPARAM, "name"},
+		{"$_private", BOUNDPARAM, "_private"},
+		{"$", DOLLAR, ""},
+	}
+
+	for _, tt := range tests {
+		s := NewScanner(strings.NewReader(tt.input))
+		tok, _, lit := s.Scan()
+		if tok != tt.tok {
+			t.Errorf("%q: tok = %v, want %v", tt.input, tok, tt.tok)
+		}
+		if lit != tt.lit {
+			t.Errorf("%q: lit = %q, want %q", tt.input, lit, tt.lit)
+		}
+	}
+}
@@ -4,13 +4,19 @@ import (
 	"io"
 )
 
+// tokenBufferSize is the number of tokens the circular buffer can hold
+// unread at once. It's larger than a single token of lookahead needs
+// because an indentation-aware Scanner (see NewIndentScanner) can emit a
+// burst of synthetic OUTDENT tokens for one physical newline.
+const tokenBufferSize = 16
+
 // TokenBuffer represents a wrapper for scanner to add a buffer.
 // It provides a fixed-length circular buffer that can be unread.
 type TokenBuffer struct {
 	s   *Scanner
 	i   int // buffer index
 	n   int // buffer size
-	buf [6]struct {
+	buf [tokenBufferSize]struct {
 		tok Token
 		pos Pos
 		lit string
@@ -22,6 +28,26 @@ func NewTokenBuffer(r io.Reader) *TokenBuffer {
 	return &TokenBuffer{s: NewScanner(r)}
 }
 
+// NewTokenBufferWithHandler returns a new buffered scanner for a reader
+// whose underlying Scanner reports lexical errors to err as it scans.
+func NewTokenBufferWithHandler(r io.Reader, err ErrorHandler) *TokenBuffer {
+	s := &Scanner{}
+	s.Init(r, err)
+	return &TokenBuffer{s: s}
+}
+
+// ErrorCount returns the number of lexical errors the underlying Scanner
+// has encountered so far.
+func (s *TokenBuffer) ErrorCount() int {
+	return s.s.ErrorCount
+}
+
+// NewIndentTokenBuffer returns a new buffered scanner for a reader whose
+// underlying Scanner additionally emits NEWLINE/INDENT/OUTDENT tokens.
+func NewIndentTokenBuffer(r io.Reader) *TokenBuffer {
+	return &TokenBuffer{s: NewIndentScanner(r)}
+}
+
 // Scan reads the next token from the scanner.
 func (s *TokenBuffer) Scan() (tok Token, pos Pos, lit string) {
 	return s.ScanFunc(s.s.Scan)
@@ -61,3 +87,13 @@ func (s *TokenBuffer) Current() (tok Token, pos Pos, lit string) {
 func (s *TokenBuffer) Peek() rune {
 	return s.s.Peek()
 }
+
+// ScanIgnoreComment functions like Scan except it skips over COMMENT and
+// MULTILINE_COMMENT tokens, for parsers that don't care about comments.
+func (s *TokenBuffer) ScanIgnoreComment() (tok Token, pos Pos, lit string) {
+	for {
+		if tok, pos, lit = s.Scan(); tok != COMMENT && tok != MULTILINE_COMMENT {
+			return
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanner_Scan_Comments(t *testing.T) {
+	tests := []struct {
+		input string
+		tok   Token
+		lit   string
+	}{
+		{"-- a line comment", COMMENT, "-- a line comment"},
+		{"// a line comment", COMMENT, "// a line comment"},
+		{"/* a block comment */", MULTILINE_COMMENT, "/* a block comment */"},
+		{"/* spans\nmultiple\nlines */", MULTILINE_COMMENT, "/* spans\nmultiple\nlines */"},
+	}
+
+	for _, tt := range tests {
+		s := NewScanner(strings.NewReader(tt.input))
+		tok, _, lit := s.Scan()
+		if tok != tt.tok {
+			t.Errorf("%q: tok = %v, want %v", tt.input, tok, tt.tok)
+		}
+		if lit != tt.lit {
+			t.Errorf("%q: lit = %q, want %q", tt.input, lit, tt.lit)
+		}
+	}
+}
+
+// TestScanner_Scan_UnterminatedBlockComment verifies that an unterminated
+// "/* ..." reports an error through ErrorHandler, mirroring BADSTRING and
+// BADREGEX.
+func TestScanner_Scan_UnterminatedBlockComment(t *testing.T) {
+	var got []string
+	s := &Scanner{}
+	s.Init(strings.NewReader("/* never closed"), func(pos Pos, msg string) {
+		got = append(got, msg)
+	})
+
+	tok, _, lit := s.Scan()
+	if tok != MULTILINE_COMMENT {
+		t.Fatalf("tok = %v, want MULTILINE_COMMENT", tok)
+	}
+	if lit != "/* never closed" {
+		t.Fatalf("lit = %q, want %q", lit, "/* never closed")
+	}
+	if len(got) != 1 || got[0] != "unterminated block comment" {
+		t.Fatalf("ErrorHandler calls = %v, want [\"unterminated block comment\"]", got)
+	}
+	if s.ErrorCount != 1 {
+		t.Fatalf("ErrorCount = %d, want 1", s.ErrorCount)
+	}
+}
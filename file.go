@@ -0,0 +1,79 @@
+package lexer
+
+import "sort"
+
+// NOTE: File and FileSet follow the same model as go/token.File and
+// cue/token: a FileSet mints Files, and each File records the byte
+// offset each line starts at so that a byte offset can be translated
+// back into a line/column pair carrying the originating filename.
+
+// File holds the line-offset table for a single source file handed to a
+// Scanner via InitFile.
+type File struct {
+	name  string
+	lines []int // byte offsets of line starts, always beginning with 0
+	size  int    // largest offset seen so far
+}
+
+// Name returns the filename the File was created with.
+func (f *File) Name() string { return f.name }
+
+// AddLine records that a new line begins at offset. Offsets must be
+// added in increasing order; out-of-order or duplicate offsets are
+// ignored.
+func (f *File) AddLine(offset int) {
+	if n := len(f.lines); n == 0 || f.lines[n-1] < offset {
+		f.lines = append(f.lines, offset)
+	}
+	if offset > f.size {
+		f.size = offset
+	}
+}
+
+// Position translates a byte offset within the file into a Pos carrying
+// the file's name along with the resulting line and column.
+func (f *File) Position(offset int) Pos {
+	i := sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset }) - 1
+	if i < 0 {
+		i = 0
+	}
+	return Pos{
+		Filename: f.name,
+		Offset:   offset,
+		Line:     i,
+		Char:     offset - f.lines[i],
+	}
+}
+
+// offsetOf computes the byte offset pos.Line/pos.Char corresponds to
+// within f, using the line table built up so far via AddLine. It's used
+// to translate a scanner-local Pos (which always has accurate Line/Char)
+// into a byte offset suitable for a later Position lookup.
+func (f *File) offsetOf(pos Pos) int {
+	i := pos.Line
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(f.lines) {
+		i = len(f.lines) - 1
+	}
+	return f.lines[i] + pos.Char
+}
+
+// FileSet mints Files for use with Scanner.InitFile, so that tools
+// working across several files can resolve positions consistently.
+type FileSet struct {
+	files []*File
+}
+
+// NewFileSet returns a new, empty FileSet.
+func NewFileSet() *FileSet {
+	return &FileSet{}
+}
+
+// AddFile creates a new, empty File named name and adds it to the set.
+func (s *FileSet) AddFile(name string) *File {
+	f := &File{name: name, lines: []int{0}}
+	s.files = append(s.files, f)
+	return f
+}
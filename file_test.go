@@ -0,0 +1,67 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFile_Position(t *testing.T) {
+	f := &File{name: "x.ql", lines: []int{0}}
+	f.AddLine(6)  // offset of the start of line 1 ("bc\n" begins at 6)
+	f.AddLine(10) // offset of the start of line 2
+
+	tests := []struct {
+		offset   int
+		wantLine int
+		wantChar int
+	}{
+		{0, 0, 0},
+		{5, 0, 5},
+		{6, 1, 0},
+		{9, 1, 3},
+		{10, 2, 0},
+	}
+
+	for _, tt := range tests {
+		pos := f.Position(tt.offset)
+		if pos.Filename != "x.ql" {
+			t.Errorf("offset %d: Filename = %q, want %q", tt.offset, pos.Filename, "x.ql")
+		}
+		if pos.Line != tt.wantLine || pos.Char != tt.wantChar {
+			t.Errorf("offset %d: Line/Char = %d/%d, want %d/%d", tt.offset, pos.Line, pos.Char, tt.wantLine, tt.wantChar)
+		}
+	}
+}
+
+// TestScanner_InitFile verifies that tokens scanned after InitFile carry
+// the file's name and a byte offset resolved through File.Position,
+// unlike a scanner started with NewScanner/Init.
+func TestScanner_InitFile(t *testing.T) {
+	fset := NewFileSet()
+	f := fset.AddFile("x.ql")
+
+	s := &Scanner{}
+	s.InitFile(f, strings.NewReader("a\nbb\n"))
+
+	tok, pos, _ := s.Scan() // "a"
+	if tok != IDENT || pos.Filename != "x.ql" || pos.Offset != 0 {
+		t.Fatalf("token 1: tok=%v pos=%+v, want IDENT at offset 0 in x.ql", tok, pos)
+	}
+
+	s.Scan() // "\n"
+
+	tok, pos, _ = s.Scan() // "bb"
+	if tok != IDENT || pos.Filename != "x.ql" || pos.Offset != 2 {
+		t.Fatalf("token 2: tok=%v pos=%+v, want IDENT at offset 2 in x.ql", tok, pos)
+	}
+}
+
+// TestScanner_NoFile verifies that a plain Scanner leaves Filename/Offset
+// at their zero values, since it was never started with InitFile.
+func TestScanner_NoFile(t *testing.T) {
+	s := NewScanner(strings.NewReader("a"))
+	_, pos, _ := s.Scan()
+	if pos.Filename != "" || pos.Offset != 0 {
+		t.Fatalf("pos = %+v, want zero Filename/Offset", pos)
+	}
+}
@@ -0,0 +1,235 @@
+package lexer
+
+import "io"
+
+// NOTE: The layout rules implemented here follow the same approach as
+// Python and Starlark: leading whitespace on each logical line is turned
+// into INDENT/OUTDENT tokens relative to a stack of column widths, and a
+// NEWLINE token marks the end of each logical line.
+
+// defaultTabWidth is the tab stop interval used when computing
+// indentation: a tab advances the width to the next multiple of it.
+// NewIndentScanner always uses this width; no other width can be set.
+const defaultTabWidth = 8
+
+// indentState tracks the layout-sensitive scanning state used by an
+// indentation-aware Scanner. It is only present on scanners created with
+// NewIndentScanner.
+type indentState struct {
+	tabWidth int
+	stack    []int // indentation stack, always starts at [0]
+	depth    int   // bracket nesting depth; NEWLINE is suppressed while > 0
+	atBOL    bool  // true when the next token starts a new logical line
+	pending  []pendingToken
+}
+
+// pendingToken is a synthetic token (INDENT, OUTDENT, NEWLINE, ...) queued
+// up for delivery before scanning resumes.
+type pendingToken struct {
+	tok Token
+	pos Pos
+	lit string
+}
+
+// NewIndentScanner returns a new Scanner that additionally emits NEWLINE,
+// INDENT and OUTDENT tokens based on leading whitespace, in addition to
+// its normal token stream.
+func NewIndentScanner(r io.Reader) *Scanner {
+	s := NewScanner(r)
+	s.indent = &indentState{
+		tabWidth: defaultTabWidth,
+		stack:    []int{0},
+		atBOL:    true,
+	}
+	return s
+}
+
+// scanIndent is the Scan implementation used once indentation tracking
+// has been enabled.
+func (s *Scanner) scanIndent() (tok Token, pos Pos, lit string) {
+	st := s.indent
+
+	if p, ok := st.pop(); ok {
+		return p.tok, p.pos, p.lit
+	}
+
+	if st.atBOL && st.depth == 0 {
+		for {
+			width, atEOF, blank, p := s.measureIndent()
+			if blank {
+				continue
+			}
+			st.atBOL = false
+			if !atEOF {
+				s.adjustIndent(width, p)
+			}
+			break
+		}
+		if p, ok := st.pop(); ok {
+			return p.tok, p.pos, p.lit
+		}
+	}
+
+	tok, pos, lit = s.scan()
+
+	switch tok {
+	case LPAREN, LBRACKET, LCURLY:
+		st.depth++
+	case RPAREN, RBRACKET, RCURLY:
+		if st.depth > 0 {
+			st.depth--
+		}
+	case WS:
+		if st.depth == 0 && containsNewline(lit) {
+			st.atBOL = true
+			return NEWLINE, pos, ""
+		}
+	case EOF:
+		for len(st.stack) > 1 {
+			st.stack = st.stack[:len(st.stack)-1]
+			st.pending = append(st.pending, pendingToken{OUTDENT, pos, ""})
+		}
+		if p, ok := st.pop(); ok {
+			st.pending = append(st.pending, pendingToken{EOF, pos, ""})
+			return p.tok, p.pos, p.lit
+		}
+	}
+
+	return tok, pos, lit
+}
+
+// pop removes and returns the next queued synthetic token, if any.
+func (st *indentState) pop() (pendingToken, bool) {
+	if len(st.pending) == 0 {
+		return pendingToken{}, false
+	}
+	p := st.pending[0]
+	st.pending = st.pending[1:]
+	return p, true
+}
+
+// adjustIndent compares width against the indentation stack, queuing an
+// INDENT or the appropriate number of OUTDENT tokens.
+func (s *Scanner) adjustIndent(width int, pos Pos) {
+	st := s.indent
+	top := st.stack[len(st.stack)-1]
+
+	switch {
+	case width > top:
+		st.stack = append(st.stack, width)
+		st.pending = append(st.pending, pendingToken{INDENT, pos, ""})
+	case width < top:
+		for len(st.stack) > 1 && st.stack[len(st.stack)-1] > width {
+			st.stack = st.stack[:len(st.stack)-1]
+			st.pending = append(st.pending, pendingToken{OUTDENT, pos, ""})
+		}
+		if st.stack[len(st.stack)-1] != width {
+			const msg = "unindent does not match any outer indentation level"
+			s.error(pos, msg)
+			st.pending = append(st.pending, pendingToken{ILLEGAL, pos, msg})
+		}
+	}
+}
+
+// measureIndent consumes the leading whitespace of a logical line and
+// returns its column width. blank is true for empty or comment-only
+// lines, which don't affect indentation and should be skipped.
+func (s *Scanner) measureIndent() (width int, atEOF, blank bool, pos Pos) {
+	width = 0
+	first := true
+
+	for {
+		ch, p := s.r.read()
+		if first {
+			pos = p
+			first = false
+		}
+
+		switch {
+		case ch == eof:
+			return width, true, false, pos
+		case ch == ' ':
+			width++
+		case ch == '\t':
+			width += s.indent.tabWidth - (width % s.indent.tabWidth)
+		case ch == '\n':
+			s.addLine(p)
+			return 0, false, true, pos
+		case ch == '-':
+			if ch1, _ := s.r.read(); ch1 == '-' {
+				s.skipToLineEnd()
+				return 0, false, true, pos
+			}
+			s.r.unread()
+			s.r.unread()
+			return width, false, false, pos
+		case ch == '/':
+			if ch1, _ := s.r.read(); ch1 == '/' {
+				s.skipToLineEnd()
+				return 0, false, true, pos
+			} else if ch1 == '*' {
+				if s.skipBlockComment() {
+					width = 0
+				}
+				continue
+			}
+			s.r.unread()
+			s.r.unread()
+			return width, false, false, pos
+		default:
+			s.r.unread()
+			return width, false, false, pos
+		}
+	}
+}
+
+// skipToLineEnd discards input up to, but not including, the next
+// newline or EOF.
+func (s *Scanner) skipToLineEnd() {
+	for {
+		ch, _ := s.r.read()
+		if ch == eof || ch == '\n' {
+			if ch == '\n' {
+				s.r.unread()
+			}
+			return
+		}
+	}
+}
+
+// skipBlockComment discards input up to and including the closing "*/"
+// of a "/* ... */" comment whose opening "/*" has already been consumed,
+// recording any newlines crossed with the scanner's File. It reports
+// whether the comment contained a newline, so the caller knows whether
+// the indentation measured before it still applies. An unterminated
+// comment is reported through s.error, mirroring scanBlockComment.
+func (s *Scanner) skipBlockComment() (sawNewline bool) {
+	_, start := s.r.curr()
+	for {
+		ch, p := s.r.read()
+		if ch == eof {
+			s.error(start, "unterminated block comment")
+			return sawNewline
+		}
+		if ch == '\n' {
+			s.addLine(p)
+			sawNewline = true
+		}
+		if ch == '*' {
+			if ch1, _ := s.r.read(); ch1 == '/' {
+				return sawNewline
+			}
+			s.r.unread()
+		}
+	}
+}
+
+// containsNewline reports whether s contains a newline character.
+func containsNewline(s string) bool {
+	for _, ch := range s {
+		if ch == '\n' {
+			return true
+		}
+	}
+	return false
+}
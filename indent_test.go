@@ -0,0 +1,83 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIndentScanner_Scan(t *testing.T) {
+	input := "a\n  b\n    c\nd\n"
+	want := []Token{
+		IDENT, NEWLINE,
+		INDENT, IDENT, NEWLINE,
+		INDENT, IDENT, NEWLINE,
+		OUTDENT, OUTDENT, IDENT, NEWLINE,
+		EOF,
+	}
+
+	s := NewIndentScanner(strings.NewReader(input))
+	for i, wantTok := range want {
+		tok, _, _ := s.Scan()
+		if tok != wantTok {
+			t.Fatalf("token %d: tok = %v, want %v", i, tok, wantTok)
+		}
+	}
+}
+
+// TestIndentScanner_Scan_MismatchedUnindent verifies that an unindent
+// which doesn't match any outer indentation level is reported through
+// ErrorHandler, in addition to the ILLEGAL token it already produced.
+func TestIndentScanner_Scan_MismatchedUnindent(t *testing.T) {
+	var got []string
+	s := NewIndentScanner(strings.NewReader("a\n    b\n  c\n"))
+	s.ErrorHandler = func(pos Pos, msg string) {
+		got = append(got, msg)
+	}
+
+	var toks []Token
+	for {
+		tok, _, _ := s.Scan()
+		toks = append(toks, tok)
+		if tok == EOF {
+			break
+		}
+	}
+
+	foundIllegal := false
+	for _, tok := range toks {
+		if tok == ILLEGAL {
+			foundIllegal = true
+		}
+	}
+	if !foundIllegal {
+		t.Fatalf("tokens = %v, want an ILLEGAL token for the mismatched unindent", toks)
+	}
+
+	const want = "unindent does not match any outer indentation level"
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("ErrorHandler calls = %v, want [%q]", got, want)
+	}
+	if s.ErrorCount != 1 {
+		t.Fatalf("ErrorCount = %d, want 1", s.ErrorCount)
+	}
+}
+
+// TestIndentScanner_Scan_BlockCommentOnlyLine verifies that a line
+// consisting solely of a block comment doesn't affect indentation, same
+// as a blank or "--"/"//" comment-only line.
+func TestIndentScanner_Scan_BlockCommentOnlyLine(t *testing.T) {
+	input := "a\n  /* just a comment */\n  b\n"
+	want := []Token{
+		IDENT, NEWLINE,
+		INDENT, IDENT, NEWLINE,
+		OUTDENT, EOF,
+	}
+
+	s := NewIndentScanner(strings.NewReader(input))
+	for i, wantTok := range want {
+		tok, _, _ := s.Scan()
+		if tok != wantTok {
+			t.Fatalf("token %d: tok = %v, want %v", i, tok, wantTok)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanner_Scan_Numbers(t *testing.T) {
+	tests := []struct {
+		input string
+		tok   Token
+		lit   string
+	}{
+		{"123", INTEGER, "123"},
+		{"1.5", DECIMAL, "1.5"},
+		{"1.5e-10", DECIMAL, "1.5e-10"},
+		{"2E+3", DECIMAL, "2E+3"},
+		{"1_000_000", INTEGER, "1_000_000"},
+		{"5s", DURATION, "5s"},
+		{"100ms", DURATION, "100ms"},
+		{"0xFF", HEX, "0xFF"},
+		{"0xFF_FF", HEX, "0xFF_FF"},
+		{"0o17", OCTAL, "0o17"},
+		{"0b101", BINARY, "0b101"},
+	}
+
+	for _, tt := range tests {
+		s := NewScanner(strings.NewReader(tt.input))
+		tok, _, lit := s.Scan()
+		if tok != tt.tok {
+			t.Errorf("%q: tok = %v, want %v", tt.input, tok, tt.tok)
+		}
+		if lit != tt.lit {
+			t.Errorf("%q: lit = %q, want %q", tt.input, lit, tt.lit)
+		}
+	}
+}
+
+// TestScanner_Scan_BasedNumber_MalformedSeparator verifies that a "_"
+// digit separator not sandwiched between two valid digits of the literal's
+// base makes the whole literal ILLEGAL. Plain decimal literals differ:
+// scanDigits ends the literal just before a misplaced separator.
+func TestScanner_Scan_BasedNumber_MalformedSeparator(t *testing.T) {
+	tests := []string{"0xFF_", "0x__FF", "0x_FF_", "0x_"}
+
+	for _, input := range tests {
+		s := NewScanner(strings.NewReader(input))
+		tok, _, lit := s.Scan()
+		if tok != ILLEGAL {
+			t.Errorf("%q: tok = %v, want ILLEGAL (lit %q)", input, tok, lit)
+		}
+	}
+}
+
+func TestToken_Base(t *testing.T) {
+	tests := []struct {
+		tok  Token
+		base int
+	}{
+		{HEX, 16},
+		{OCTAL, 8},
+		{BINARY, 2},
+		{INTEGER, 10},
+		{DURATION, 10},
+		{DECIMAL, 0},
+		{IDENT, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.tok.Base(); got != tt.base {
+			t.Errorf("%v.Base() = %d, want %d", tt.tok, got, tt.base)
+		}
+	}
+}
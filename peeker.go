@@ -0,0 +1,103 @@
+package lexer
+
+import "context"
+
+// NOTE: This mirrors the channel-based lookahead approach used by
+// hashicorp/hil's scanner.Peeker: a goroutine drains a Scanner into a
+// channel, and a Peeker buffers just enough of that channel to support
+// arbitrary lookahead. It's an alternative to TokenBuffer for parsers that
+// need more than a few tokens of lookahead.
+
+// ScannedToken is a single token delivered over the channel returned by
+// Scanner.Tokens.
+type ScannedToken struct {
+	Tok Token
+	Pos Pos
+	Lit string
+}
+
+// Tokens scans s until EOF, sending each token on the returned channel.
+// The channel is closed once EOF is reached or ctx is done, whichever
+// comes first.
+func (s *Scanner) Tokens(ctx context.Context) <-chan ScannedToken {
+	ch := make(chan ScannedToken)
+
+	go func() {
+		defer close(ch)
+		for {
+			tok, pos, lit := s.Scan()
+			select {
+			case ch <- ScannedToken{Tok: tok, Pos: pos, Lit: lit}:
+			case <-ctx.Done():
+				return
+			}
+			if tok == EOF {
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// Peeker wraps a Scanner's token channel and buffers tokens as needed to
+// support arbitrary lookahead, as an allocation-friendlier alternative to
+// TokenBuffer's fixed-size circular buffer.
+type Peeker struct {
+	ch     <-chan ScannedToken
+	cancel context.CancelFunc
+	buf    []ScannedToken
+}
+
+// NewPeeker returns a Peeker that scans s in a background goroutine.
+func NewPeeker(s *Scanner) *Peeker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Peeker{ch: s.Tokens(ctx), cancel: cancel}
+}
+
+// fill buffers tokens until at least n+1 are available or the scanner is
+// exhausted.
+func (p *Peeker) fill(n int) {
+	for len(p.buf) <= n {
+		if len(p.buf) > 0 && p.buf[len(p.buf)-1].Tok == EOF {
+			return
+		}
+		st, ok := <-p.ch
+		if !ok {
+			return
+		}
+		p.buf = append(p.buf, st)
+	}
+}
+
+// Peek returns the next token without consuming it.
+func (p *Peeker) Peek() ScannedToken {
+	return p.PeekN(0)
+}
+
+// PeekN returns the token n positions ahead of the next one (PeekN(0) is
+// equivalent to Peek) without consuming any input. Looking past EOF keeps
+// returning EOF.
+func (p *Peeker) PeekN(n int) ScannedToken {
+	p.fill(n)
+	if n >= len(p.buf) {
+		return ScannedToken{Tok: EOF}
+	}
+	return p.buf[n]
+}
+
+// Read consumes and returns the next token.
+func (p *Peeker) Read() ScannedToken {
+	st := p.Peek()
+	if len(p.buf) > 0 {
+		p.buf = p.buf[1:]
+	}
+	return st
+}
+
+// Close stops the background scan goroutine. It should be called once
+// the Peeker is no longer needed, even if the underlying Scanner already
+// reached EOF.
+func (p *Peeker) Close() {
+	p.cancel()
+}
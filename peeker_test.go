@@ -0,0 +1,49 @@
+package lexer
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPeeker_PeekAndRead(t *testing.T) {
+	s := NewScanner(strings.NewReader("a b c"))
+	p := NewPeeker(s)
+	defer p.Close()
+
+	if tok := p.PeekN(2).Tok; tok != IDENT {
+		t.Fatalf("PeekN(2).Tok = %v, want IDENT", tok)
+	}
+
+	want := []Token{IDENT, WS, IDENT, WS, IDENT, EOF}
+	for i, wantTok := range want {
+		if peeked := p.Peek().Tok; peeked != wantTok {
+			t.Fatalf("token %d: Peek().Tok = %v, want %v", i, peeked, wantTok)
+		}
+		if read := p.Read().Tok; read != wantTok {
+			t.Fatalf("token %d: Read().Tok = %v, want %v", i, read, wantTok)
+		}
+	}
+
+	// Reading past EOF should keep returning EOF rather than blocking.
+	if tok := p.Read().Tok; tok != EOF {
+		t.Fatalf("Read() past EOF = %v, want EOF", tok)
+	}
+}
+
+func TestScanner_Tokens_ContextCancel(t *testing.T) {
+	s := NewScanner(strings.NewReader("a b c"))
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := s.Tokens(ctx)
+
+	if st := <-ch; st.Tok != IDENT {
+		t.Fatalf("first token = %v, want IDENT", st.Tok)
+	}
+
+	cancel()
+
+	// The channel must close once the context is done, even though the
+	// scanner hasn't reached EOF yet.
+	for range ch {
+	}
+}
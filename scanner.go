@@ -10,9 +10,35 @@ import (
 // NOTE: The code below is HEAVILY influenced by the InfluxQL parser and lexer.
 // You can find the InfluxDB code at https://github.com/influxdb/influxdb/blob/master/influxql/scanner.go
 
+// ErrorHandler is called for each lexical error encountered while scanning,
+// such as an unterminated string or a bad escape sequence. pos is the
+// position the error was detected at and msg describes the problem.
+type ErrorHandler func(pos Pos, msg string)
+
 // Scanner represents a lexical scanner for InfluxQL.
 type Scanner struct {
 	r *reader
+
+	// ErrorHandler, if non-nil, is invoked whenever the scanner encounters
+	// a malformed string, escape sequence, regex or number literal, an
+	// unterminated block comment, or (in indentation-aware mode) an
+	// unindent matching no outer level. The scanner still returns the token
+	// it would return without a handler (BAD* for strings, escapes and
+	// regexes) so callers that don't set one keep working as before.
+	ErrorHandler ErrorHandler
+
+	// ErrorCount is incremented every time ErrorHandler would be invoked,
+	// regardless of whether a handler is actually set.
+	ErrorCount int
+
+	// indent holds the layout-tracking state for an indentation-aware
+	// scanner created with NewIndentScanner. It is nil otherwise.
+	indent *indentState
+
+	// file, if non-nil, receives AddLine calls as newlines are consumed
+	// and is used to translate every Pos the scanner returns through
+	// file.Position so it carries a filename and byte offset.
+	file *File
 }
 
 // NewScanner returns a new instance of Scanner.
@@ -20,10 +46,65 @@ func NewScanner(r io.Reader) *Scanner {
 	return &Scanner{r: &reader{r: bufio.NewReader(r)}}
 }
 
+// Init reinitializes the scanner to read from r, reporting lexical errors
+// to err as they're encountered in addition to returning the BAD* tokens.
+// Passing a nil err disables error reporting.
+func (s *Scanner) Init(r io.Reader, err ErrorHandler) {
+	s.r = &reader{r: bufio.NewReader(r)}
+	s.ErrorHandler = err
+	s.ErrorCount = 0
+}
+
+// InitFile reinitializes the scanner to read from r, recording the
+// offsets of newlines it consumes in f. This lets positions later be
+// resolved through f.Position to include a filename and byte offset
+// rather than just a line/char pair.
+func (s *Scanner) InitFile(f *File, r io.Reader) {
+	s.r = &reader{r: bufio.NewReader(r)}
+	s.file = f
+}
+
+// addLine records, in s.file, that a new line begins right after the
+// newline at pos (the position of the '\n' rune itself, as returned by
+// the reader). It's a no-op unless the scanner was started with InitFile.
+func (s *Scanner) addLine(pos Pos) {
+	if s.file == nil {
+		return
+	}
+	s.file.AddLine(s.file.offsetOf(pos) + 1)
+}
+
+// error records a lexical error at pos, invoking ErrorHandler if one has
+// been set. When started with InitFile, pos is resolved through the file
+// first, the same as the positions Scan returns.
+func (s *Scanner) error(pos Pos, msg string) {
+	s.ErrorCount++
+	if s.file != nil {
+		pos = s.file.Position(s.file.offsetOf(pos))
+	}
+	if s.ErrorHandler != nil {
+		s.ErrorHandler(pos, msg)
+	}
+}
+
 // Scan returns the next token and position from the underlying reader.
 // Also returns the literal text read for strings and numbers tokens
 // since these token types can have different literal representations.
 func (s *Scanner) Scan() (tok Token, pos Pos, lit string) {
+	if s.indent != nil {
+		tok, pos, lit = s.scanIndent()
+	} else {
+		tok, pos, lit = s.scan()
+	}
+	if s.file != nil {
+		pos = s.file.Position(s.file.offsetOf(pos))
+	}
+	return tok, pos, lit
+}
+
+// scan performs the actual lexical analysis; Scan wraps it to optionally
+// layer indentation tracking on top.
+func (s *Scanner) scan() (tok Token, pos Pos, lit string) {
 
 	// Read next code point.
 	ch0, pos := s.r.read()
@@ -56,11 +137,23 @@ func (s *Scanner) Scan() (tok Token, pos Pos, lit string) {
 			return s.scanNumber()
 		}
 		return DOT, pos, ""
-	case '+', '-':
+	case '+':
+		return s.scanNumber()
+	case '-':
+		if ch1, _ := s.r.read(); ch1 == '-' {
+			return s.scanLineComment(pos, "--")
+		}
+		s.r.unread()
 		return s.scanNumber()
 	case '*':
 		return MUL, pos, ""
 	case '/':
+		if ch1, _ := s.r.read(); ch1 == '/' {
+			return s.scanLineComment(pos, "//")
+		} else if ch1 == '*' {
+			return s.scanBlockComment(pos)
+		}
+		s.r.unread()
 		return DIV, pos, ""
 	case '=':
 		if ch1, _ := s.r.read(); ch1 == '~' {
@@ -120,6 +213,11 @@ func (s *Scanner) Scan() (tok Token, pos Pos, lit string) {
 	case '%':
 		return PERCENT, pos, ``
 	case '$':
+		if ch1, _ := s.r.read(); isLetter(ch1) || ch1 == '_' {
+			s.r.unread()
+			return BOUNDPARAM, pos, ScanBareIdent(s.r)
+		}
+		s.r.unread()
 		return DOLLAR, pos, ""
 	case '#':
 		return HASH, pos, ""
@@ -144,16 +242,26 @@ func (s *Scanner) read() rune {
 func (s *Scanner) unread() { _ = s.r.UnreadRune() }
 
 // scanWhitespace consumes the current rune and all contiguous whitespace.
+// In indent-aware mode, it stops right after a newline instead of also
+// consuming the following line's leading indentation, since measureIndent
+// needs to see that indentation itself to compute the line's width.
 func (s *Scanner) scanWhitespace() (tok Token, pos Pos, lit string) {
 	// Create a buffer and read the current character into it.
 	var buf bytes.Buffer
 	ch, pos := s.r.curr()
 	_, _ = buf.WriteRune(ch)
+	if ch == '\n' {
+		s.addLine(pos)
+		if s.indent != nil {
+			return WS, pos, buf.String()
+		}
+	}
 
 	// Read every subsequent whitespace character into the buffer.
 	// Non-whitespace characters and EOF will cause the loop to exit.
 	for {
-		ch, _ = s.r.read()
+		var p Pos
+		ch, p = s.r.read()
 		if ch == eof {
 			break
 		} else if !isWhitespace(ch) {
@@ -161,6 +269,12 @@ func (s *Scanner) scanWhitespace() (tok Token, pos Pos, lit string) {
 			break
 		} else {
 			_, _ = buf.WriteRune(ch)
+			if ch == '\n' {
+				s.addLine(p)
+				if s.indent != nil {
+					break
+				}
+			}
 		}
 	}
 
@@ -209,9 +323,11 @@ func (s *Scanner) scanString() (tok Token, pos Pos, lit string) {
 	var err error
 	lit, err = ScanString(s.r)
 	if err == errBadString {
+		s.error(pos, "unterminated string")
 		return BADSTRING, pos, lit
 	} else if err == errBadEscape {
 		_, pos = s.r.curr()
+		s.error(pos, "bad escape sequence")
 		return BADESCAPE, pos, lit
 	}
 	return STRING, pos, lit
@@ -252,6 +368,21 @@ func (s *Scanner) scanNumber() (tok Token, pos Pos, lit string) {
 		// Unread the full stop so we can read it later.
 		s.r.unread()
 	} else {
+		// Check for a 0x/0o/0b base prefix before falling back to decimal.
+		// The prefix letter must be peeked with a fresh read, not a read
+		// after unreading ch, or it would just redeliver ch instead of
+		// advancing to the next rune.
+		if ch == '0' {
+			if ch1, _ := s.r.read(); ch1 == 'x' || ch1 == 'X' {
+				return s.scanBasedNumber(pos, "0"+string(ch1), HEX, isHexDigit)
+			} else if ch1 == 'o' || ch1 == 'O' {
+				return s.scanBasedNumber(pos, "0"+string(ch1), OCTAL, isOctalDigit)
+			} else if ch1 == 'b' || ch1 == 'B' {
+				return s.scanBasedNumber(pos, "0"+string(ch1), BINARY, isBinaryDigit)
+			} else {
+				s.r.unread()
+			}
+		}
 		s.r.unread()
 	}
 
@@ -272,12 +403,41 @@ func (s *Scanner) scanNumber() (tok Token, pos Pos, lit string) {
 		s.r.unread()
 	}
 
+	// If next code point is an exponent marker (e/E) then consume the
+	// scientific notation suffix, e.g. 1.5e-10, 2E+3.
+	if ch0, _ := s.r.read(); ch0 == 'e' || ch0 == 'E' {
+		hasSign := false
+		sign, _ := s.r.read()
+		if sign == '+' || sign == '-' {
+			hasSign = true
+		} else {
+			s.r.unread()
+		}
+
+		if digits := s.scanDigits(); digits != "" {
+			_, _ = buf.WriteRune(ch0)
+			if hasSign {
+				_, _ = buf.WriteRune(sign)
+			}
+			_, _ = buf.WriteString(digits)
+			return DECIMAL, pos, buf.String()
+		}
+
+		// Not a valid exponent after all; put everything back.
+		if hasSign {
+			s.r.unread()
+		}
+		s.r.unread()
+	} else {
+		s.r.unread()
+	}
+
 	// Attempt to read as a duration if it doesn't have a fractional part.
 	if !strings.Contains(buf.String(), ".") {
 		// If the next rune is a duration unit (u,µ,ms,s) then return a duration token
 		if ch0, _ := s.r.read(); ch0 == 'u' || ch0 == 'µ' || ch0 == 's' || ch0 == 'h' || ch0 == 'd' || ch0 == 'w' {
 			_, _ = buf.WriteRune(ch0)
-			return DURATION_VAL, pos, buf.String()
+			return DURATION, pos, buf.String()
 		} else if ch0 == 'm' {
 			_, _ = buf.WriteRune(ch0)
 			if ch1, _ := s.r.read(); ch1 == 's' {
@@ -285,25 +445,98 @@ func (s *Scanner) scanNumber() (tok Token, pos Pos, lit string) {
 			} else {
 				s.r.unread()
 			}
-			return DURATION_VAL, pos, buf.String()
+			return DURATION, pos, buf.String()
 		}
 		s.r.unread()
 	}
-	return NUMBER, pos, buf.String()
+
+	if strings.Contains(buf.String(), ".") {
+		return DECIMAL, pos, buf.String()
+	}
+	return INTEGER, pos, buf.String()
 }
 
-// scanDigits consume a contiguous series of digits.
+// scanBasedNumber consumes the digits of a 0x/0o/0b literal (allowing "_"
+// digit separators between digits, same as scanDigits) and returns tok,
+// or ILLEGAL with the malformed literal if no valid digits follow the
+// prefix, a digit outside the base's alphabet is encountered, or a "_"
+// isn't sandwiched between two valid digits.
+func (s *Scanner) scanBasedNumber(pos Pos, prefix string, tok Token, valid func(rune) bool) (Token, Pos, string) {
+	var buf bytes.Buffer
+	buf.WriteString(prefix)
+
+	ok := true
+	lastWasDigit := false
+	for {
+		ch, _ := s.r.read()
+		switch {
+		case valid(ch):
+			_, _ = buf.WriteRune(ch)
+			lastWasDigit = true
+		case ch == '_':
+			// The separator is always kept in the literal; one that isn't
+			// sandwiched between two valid digits makes it malformed.
+			_, _ = buf.WriteRune(ch)
+			if next, _ := s.r.read(); lastWasDigit && valid(next) {
+				s.r.unread()
+			} else {
+				s.r.unread()
+				ok = false
+			}
+			lastWasDigit = false
+		case isLetter(ch) || isDigit(ch):
+			// Not valid in this base; consume it so the full malformed
+			// literal is reported back to the caller.
+			_, _ = buf.WriteRune(ch)
+			ok = false
+			lastWasDigit = false
+		default:
+			s.r.unread()
+			if !ok || buf.Len() == len(prefix) {
+				s.error(pos, "malformed number literal")
+				return ILLEGAL, pos, buf.String()
+			}
+			return tok, pos, buf.String()
+		}
+	}
+}
+
+// isHexDigit returns true if the rune is a valid hexadecimal digit.
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
+
+// isOctalDigit returns true if the rune is a valid octal digit.
+func isOctalDigit(ch rune) bool { return ch >= '0' && ch <= '7' }
+
+// isBinaryDigit returns true if the rune is a valid binary digit.
+func isBinaryDigit(ch rune) bool { return ch == '0' || ch == '1' }
+
+// scanDigits consumes a contiguous series of digits, allowing "_" digit
+// separators between them (e.g. "1_000_000") so the result can be passed
+// straight to strconv.ParseInt/ParseFloat.
 func (s *Scanner) scanDigits() string {
 	var buf bytes.Buffer
 	for {
 		ch, _ := s.r.read()
-		if !isDigit(ch) {
+		switch {
+		case isDigit(ch):
+			_, _ = buf.WriteRune(ch)
+		case ch == '_':
+			// Only keep the separator if it's sandwiched between digits.
+			if next, _ := s.r.read(); isDigit(next) {
+				_, _ = buf.WriteRune('_')
+				s.r.unread()
+			} else {
+				s.r.unread()
+				s.r.unread()
+				return buf.String()
+			}
+		default:
 			s.r.unread()
-			break
+			return buf.String()
 		}
-		_, _ = buf.WriteRune(ch)
 	}
-	return buf.String()
 }
 
 func (s *Scanner) ScanRegex() (tok Token, pos Pos, lit string) {
@@ -318,13 +551,61 @@ func (s *Scanner) ScanRegex() (tok Token, pos Pos, lit string) {
 
 	if err == errBadEscape {
 		_, pos = s.r.curr()
+		s.error(pos, "bad escape sequence")
 		return BADESCAPE, pos, lit
 	} else if err != nil {
+		s.error(pos, "unterminated regular expression")
 		return BADREGEX, pos, lit
 	}
 	return REGEX, pos, string(b)
 }
 
+// scanLineComment consumes a single-line comment starting with the given
+// marker ("--" or "//") up to, but not including, the next newline.
+func (s *Scanner) scanLineComment(pos Pos, marker string) (tok Token, p Pos, lit string) {
+	var buf bytes.Buffer
+	buf.WriteString(marker)
+
+	for {
+		ch, _ := s.r.read()
+		if ch == eof || ch == '\n' {
+			s.r.unread()
+			break
+		}
+		_, _ = buf.WriteRune(ch)
+	}
+
+	return COMMENT, pos, buf.String()
+}
+
+// scanBlockComment consumes a "/* ... */" comment, tracking newlines so
+// that the position of subsequently scanned tokens stays correct.
+func (s *Scanner) scanBlockComment(pos Pos) (tok Token, p Pos, lit string) {
+	var buf bytes.Buffer
+	buf.WriteString("/*")
+
+	for {
+		ch, p := s.r.read()
+		if ch == eof {
+			s.error(pos, "unterminated block comment")
+			break
+		}
+		_, _ = buf.WriteRune(ch)
+		if ch == '\n' {
+			s.addLine(p)
+		}
+		if ch == '*' {
+			if ch1, _ := s.r.read(); ch1 == '/' {
+				_, _ = buf.WriteRune(ch1)
+				break
+			}
+			s.r.unread()
+		}
+	}
+
+	return MULTILINE_COMMENT, pos, buf.String()
+}
+
 // bufScanner represents a wrapper for scanner to add a buffer.
 // It provides a fixed-length circular buffer that can be unread.
 type bufScanner struct {
@@ -0,0 +1,43 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestScanner_ErrorHandler verifies that a malformed lexical construct is
+// both reported through ErrorHandler and reflected in ErrorCount, while
+// the scanner still returns the corresponding BAD* token so callers that
+// don't set a handler keep working exactly as before.
+func TestScanner_ErrorHandler(t *testing.T) {
+	var got []string
+	s := &Scanner{}
+	s.Init(strings.NewReader("'unterminated"), func(pos Pos, msg string) {
+		got = append(got, msg)
+	})
+
+	tok, _, _ := s.Scan()
+	if tok != BADSTRING {
+		t.Fatalf("Scan() tok = %v, want BADSTRING", tok)
+	}
+	if s.ErrorCount != 1 {
+		t.Fatalf("ErrorCount = %d, want 1", s.ErrorCount)
+	}
+	if len(got) != 1 || got[0] != "unterminated string" {
+		t.Fatalf("ErrorHandler calls = %v, want [\"unterminated string\"]", got)
+	}
+}
+
+// TestScanner_ErrorHandler_Nil verifies that a nil ErrorHandler is safe to
+// use and doesn't prevent ErrorCount from being tracked.
+func TestScanner_ErrorHandler_Nil(t *testing.T) {
+	s := &Scanner{}
+	s.Init(strings.NewReader("'unterminated"), nil)
+
+	if tok, _, _ := s.Scan(); tok != BADSTRING {
+		t.Fatalf("Scan() tok = %v, want BADSTRING", tok)
+	}
+	if s.ErrorCount != 1 {
+		t.Fatalf("ErrorCount = %d, want 1", s.ErrorCount)
+	}
+}
@@ -8,9 +8,14 @@ import "strings"
 
 // Pos specifies the line and character position of a token.
 // The Char and Line are both zero-based indexes.
+//
+// Filename and Offset are only populated when the Scanner was started
+// with InitFile; otherwise they're left at their zero values.
 type Pos struct {
-	Line int
-	Char int
+	Line     int
+	Char     int
+	Filename string
+	Offset   int
 }
 
 // Token represents each lexer symbol
@@ -45,6 +50,7 @@ const (
 
 	startLiterals
 	IDENT
+	BOUNDPARAM // $name
 	INTEGER
 	DECIMAL
 	STRING
@@ -55,6 +61,14 @@ const (
 	REGEX
 	BADREGEX
 	DURATION
+	HEX
+	OCTAL
+	BINARY
+	COMMENT
+	MULTILINE_COMMENT
+	NEWLINE
+	INDENT
+	OUTDENT
 	endLiterals
 
 	// Operators
@@ -110,15 +124,25 @@ var tokens = map[Token]string{
 	HASH:        "#",
 	ATSIGN:      "@",
 
-	IDENT:     "IDENT",
-	INTEGER:   "INTEGER",
-	DECIMAL:   "DECIMAL",
-	STRING:    "TEXTUAL",
-	DURATION:  "DURATION",
-	BADSTRING: "BADSTRING",
-	BADESCAPE: "BADESCAPE",
-	REGEX:     "REGEX",
-	BADREGEX:  "BADREGEX",
+	IDENT:      "IDENT",
+	BOUNDPARAM: "BOUNDPARAM",
+	INTEGER:    "INTEGER",
+	DECIMAL:    "DECIMAL",
+	STRING:     "TEXTUAL",
+	DURATION:   "DURATION",
+	BADSTRING:  "BADSTRING",
+	BADESCAPE:  "BADESCAPE",
+	REGEX:      "REGEX",
+	BADREGEX:   "BADREGEX",
+	HEX:        "HEX",
+	OCTAL:      "OCTAL",
+	BINARY:     "BINARY",
+
+	COMMENT:           "COMMENT",
+	MULTILINE_COMMENT: "MULTILINE_COMMENT",
+	NEWLINE:           "NEWLINE",
+	INDENT:            "INDENT",
+	OUTDENT:           "OUTDENT",
 
 	PLUS:      "+",
 	MINUS:     "-",
@@ -181,6 +205,25 @@ func (tok Token) String() string {
 	return ""
 }
 
+// Base returns the numeric base a HEX, OCTAL or BINARY literal was
+// scanned in. It returns 10 for INTEGER and DURATION literals and 0 for
+// anything else, including DECIMAL. Literals keep their 0x/0o/0b prefix
+// and any "_" separators, so an integer literal is parsed with
+// strconv.ParseInt(lit, 0, 64) rather than with Base as the base.
+func (tok Token) Base() int {
+	switch tok {
+	case HEX:
+		return 16
+	case OCTAL:
+		return 8
+	case BINARY:
+		return 2
+	case INTEGER, DURATION:
+		return 10
+	}
+	return 0
+}
+
 // Precedence returns the operator precedence of the binary operator token.
 func (tok Token) Precedence() int {
 	switch tok {